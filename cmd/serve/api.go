@@ -0,0 +1,118 @@
+package serve
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/csmith/puzzles/internal/puzzle"
+)
+
+// AnagramRequest is the body accepted by /api/v1/anagram and /api/v1/match.
+// Pattern is only used by the match endpoint, and overrides Input if set.
+type AnagramRequest struct {
+	Input     string   `json:"input"`
+	Wordlists []string `json:"wordlists,omitempty"`
+	Limit     int      `json:"limit,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+}
+
+// Match is a single solve result, exposed without kowalski's internal shape.
+type Match struct {
+	Value interface{} `json:"value"`
+}
+
+// SolveResponse is the body returned by /api/v1/anagram and /api/v1/match.
+type SolveResponse struct {
+	Matches   []Match       `json:"matches"`
+	Elapsed   time.Duration `json:"elapsed"`
+	Truncated bool          `json:"truncated"`
+}
+
+func apiAnagramHandler(writer http.ResponseWriter, request *http.Request) {
+	apiSolveHandler(writer, request, "anagram")
+}
+
+func apiMatchHandler(writer http.ResponseWriter, request *http.Request) {
+	apiSolveHandler(writer, request, "match")
+}
+
+func apiSolveHandler(writer http.ResponseWriter, request *http.Request, op string) {
+	var req AnagramRequest
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		writeNegotiated(writer, request, http.StatusBadRequest, SolveResponse{}, err)
+		return
+	}
+
+	response := solve(op, req)
+	writeNegotiated(writer, request, http.StatusOK, response, nil)
+}
+
+func solve(op string, req AnagramRequest) SolveResponse {
+	start := time.Now()
+
+	input := req.Input
+	if op == "match" && req.Pattern != "" {
+		input = req.Pattern
+	}
+
+	checkers := registry.Select(req.Wordlists)
+
+	var stream <-chan puzzle.Result
+	if op == "anagram" {
+		stream = puzzle.StreamAnagram(nil, checkers, input)
+	} else {
+		stream = puzzle.StreamMatch(nil, checkers, input)
+	}
+
+	var matches []Match
+	truncated := false
+	for result := range stream {
+		for _, word := range result.Words {
+			if req.Limit > 0 && len(matches) >= req.Limit {
+				truncated = true
+				break
+			}
+			matches = append(matches, Match{Value: word})
+		}
+	}
+
+	return SolveResponse{Matches: matches, Elapsed: time.Since(start), Truncated: truncated}
+}
+
+// writeNegotiated writes response as JSON, or as a plain text table if the
+// client asked for text/plain. It owns the status-code write so that
+// Content-Type is always set before headers are frozen.
+func writeNegotiated(writer http.ResponseWriter, request *http.Request, status int, response SolveResponse, err error) {
+	if strings.Contains(request.Header.Get("Accept"), "text/plain") {
+		writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writer.WriteHeader(status)
+		if err != nil {
+			_, _ = fmt.Fprintf(writer, "error: %s\n", err.Error())
+			return
+		}
+		tw := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(tw, "MATCH\n")
+		for _, m := range response.Matches {
+			_, _ = fmt.Fprintf(tw, "%v\n", m.Value)
+		}
+		_ = tw.Flush()
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	if err != nil {
+		_ = json.NewEncoder(writer).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+func openAPIHandler(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "application/yaml")
+	_, _ = writer.Write(openAPISpec)
+}
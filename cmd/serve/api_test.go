@@ -0,0 +1,92 @@
+package serve
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var apiTestRegistryOnce sync.Once
+
+// ensureAPITestRegistry loads a small wordlist into the package-level
+// registry once, so the handlers under test have something to solve
+// against.
+func ensureAPITestRegistry(t *testing.T) {
+	t.Helper()
+	apiTestRegistryOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "puzzles-api-test")
+		if err != nil {
+			t.Fatalf("MkdirTemp: %s", err.Error())
+		}
+		if err := os.WriteFile(filepath.Join(dir, "test.txt"), []byte("cat\ndog\ntac\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %s", err.Error())
+		}
+		if err := registry.Load(dir); err != nil {
+			t.Fatalf("registry.Load: %s", err.Error())
+		}
+	})
+}
+
+func TestAPISolveHandlerJSON(t *testing.T) {
+	ensureAPITestRegistry(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/anagram", strings.NewReader(`{"input":"tac"}`))
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+
+	apiAnagramHandler(rec, req)
+
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var resp SolveResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response wasn't valid JSON: %s (body: %s)", err.Error(), rec.Body.String())
+	}
+}
+
+func TestAPISolveHandlerTextPlain(t *testing.T) {
+	ensureAPITestRegistry(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/anagram", strings.NewReader(`{"input":"tac"}`))
+	req.Header.Set("Accept", "text/plain")
+	rec := httptest.NewRecorder()
+
+	apiAnagramHandler(rec, req)
+
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain prefix", got)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), "MATCH") {
+		t.Errorf("body doesn't look like a text table: %s", rec.Body.String())
+	}
+}
+
+func TestAPISolveHandlerBadRequestStillNegotiatesContentType(t *testing.T) {
+	ensureAPITestRegistry(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/anagram", strings.NewReader(`not json`))
+	req.Header.Set("Accept", "text/plain")
+	rec := httptest.NewRecorder()
+
+	apiAnagramHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain prefix even on the decode-error path", got)
+	}
+}
@@ -0,0 +1,9 @@
+package serve
+
+import "embed"
+
+//go:embed templates/* static/*
+var embeddedAssets embed.FS
+
+//go:embed openapi.yaml
+var openAPISpec []byte
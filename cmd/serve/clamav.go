@@ -0,0 +1,71 @@
+package serve
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"time"
+)
+
+// clamavDialTimeout bounds how long we'll wait to connect to clamd.
+const clamavDialTimeout = 5 * time.Second
+
+// clamavScanTimeout bounds the whole INSTREAM exchange, so a slow or
+// misbehaving clamd can't tie up a goroutine per upload indefinitely. It's a
+// var rather than a const so tests can shrink it.
+var clamavScanTimeout = 30 * time.Second
+
+// scanUpload sends data to a clamd instance listening on addr using the
+// INSTREAM protocol, returning an error if the scan can't be completed or
+// the file is flagged.
+func scanUpload(addr string, data []byte) error {
+	conn, err := net.DialTimeout("tcp", addr, clamavDialTimeout)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if err := conn.SetDeadline(time.Now().Add(clamavScanTimeout)); err != nil {
+		return err
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return err
+	}
+
+	const chunkSize = 4096
+	for len(data) > 0 {
+		n := chunkSize
+		if n > len(data) {
+			n = len(data)
+		}
+
+		size := make([]byte, 4)
+		binary.BigEndian.PutUint32(size, uint32(n))
+		if _, err := conn.Write(size); err != nil {
+			return err
+		}
+		if _, err := conn.Write(data[:n]); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return err
+	}
+
+	response, err := io.ReadAll(conn)
+	if err != nil {
+		return err
+	}
+	response = bytes.TrimRight(response, "\x00")
+
+	if !bytes.Contains(response, []byte("OK")) {
+		return errors.New(string(response))
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package serve
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeClamd starts a listener that speaks just enough of the INSTREAM
+// protocol to drain a scanUpload call, then replies with response.
+func fakeClamd(t *testing.T, response string) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %s", err.Error())
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		header := make([]byte, len("zINSTREAM\x00"))
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+
+		for {
+			size := make([]byte, 4)
+			if _, err := io.ReadFull(conn, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			if n == 0 {
+				break
+			}
+			if _, err := io.CopyN(io.Discard, conn, int64(n)); err != nil {
+				return
+			}
+		}
+
+		_, _ = conn.Write([]byte(response))
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestScanUploadAcceptsClean(t *testing.T) {
+	addr := fakeClamd(t, "stream: OK\x00")
+	if err := scanUpload(addr, []byte("hello world")); err != nil {
+		t.Fatalf("expected a clean scan to succeed, got: %s", err.Error())
+	}
+}
+
+func TestScanUploadRejectsInfected(t *testing.T) {
+	addr := fakeClamd(t, "stream: Eicar-Test-Signature FOUND\x00")
+	if err := scanUpload(addr, []byte("hello world")); err == nil {
+		t.Fatal("expected an infected stream to be rejected")
+	}
+}
+
+func TestScanUploadRespectsScanDeadline(t *testing.T) {
+	old := clamavScanTimeout
+	clamavScanTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { clamavScanTimeout = old })
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %s", err.Error())
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		// Never read or respond, simulating a clamd that hangs.
+		time.Sleep(time.Second)
+	}()
+
+	start := time.Now()
+	err = scanUpload(ln.Addr().String(), []byte("hello world"))
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("expected scanUpload to time out against a hanging clamd")
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Fatalf("scanUpload took %s to time out, want well under 500ms", elapsed)
+	}
+}
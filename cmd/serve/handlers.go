@@ -0,0 +1,123 @@
+package serve
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/csmith/puzzles/internal/puzzle"
+)
+
+// listsFrom parses the comma-separated ?lists= query parameter into the
+// names a Registry understands. An empty result selects every wordlist.
+func listsFrom(request *http.Request) []string {
+	raw := request.FormValue("lists")
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, ",")
+}
+
+func indexHandler(writer http.ResponseWriter, request *http.Request) {
+	if request.URL.Path == "/" {
+		err := templates.ExecuteTemplate(writer, "index.html", "")
+		if err != nil {
+			log.Printf("Fucked up: %s", err.Error())
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	} else {
+		writer.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func faviconHandler(w http.ResponseWriter, r *http.Request) {
+	file, err := embeddedAssets.Open("static/favicon.ico")
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	http.ServeContent(w, r, "favicon.ico", info.ModTime(), file.(io.ReadSeeker))
+}
+
+func anagramHandler(writer http.ResponseWriter, request *http.Request) {
+	input := request.FormValue("input")
+	writer.Header().Add("Content-Type", "application/json")
+	outputBytes, outputStatus := puzzle.Anagram(registry.Select(listsFrom(request)), input)
+	writer.WriteHeader(outputStatus)
+	_, _ = writer.Write(outputBytes)
+}
+
+func matchHandler(writer http.ResponseWriter, request *http.Request) {
+	input := request.FormValue("input")
+	writer.Header().Add("Content-Type", "application/json")
+	outputBytes, outputStatus := puzzle.Match(registry.Select(listsFrom(request)), input)
+	writer.WriteHeader(outputStatus)
+	_, _ = writer.Write(outputBytes)
+}
+
+func wordlistsHandler(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Add("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(writer).Encode(registry.List())
+}
+
+func exifUpload(writer http.ResponseWriter, request *http.Request) {
+	if maxUploadBytes > 0 {
+		request.Body = http.MaxBytesReader(writer, request.Body, maxUploadBytes)
+	}
+
+	writer.Header().Add("Content-Type", "application/json")
+
+	file, _, err := request.FormFile("exifFile")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writer.WriteHeader(http.StatusRequestEntityTooLarge)
+			_, _ = writer.Write(puzzle.Marshal(puzzle.Output{Success: false, Result: "file too large"}))
+		} else {
+			writer.WriteHeader(http.StatusBadRequest)
+			_, _ = writer.Write(puzzle.Marshal(puzzle.Output{Success: false, Result: "missing or invalid exifFile upload"}))
+		}
+		log.Println("Error Getting File", err)
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = writer.Write(puzzle.Marshal(puzzle.Output{Success: false, Result: "unable to read upload"}))
+		log.Println("Error Reading File", err)
+		return
+	}
+
+	if clamAVAddr != "" {
+		if err := scanUpload(clamAVAddr, data); err != nil {
+			log.Printf("Rejecting upload: %s", err.Error())
+			writer.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = writer.Write(puzzle.Marshal(puzzle.Output{Success: false, Result: "upload failed virus scan"}))
+			return
+		}
+	}
+
+	outputBytes, outputStatus := puzzle.GetImageResults(bytes.NewReader(data))
+	writer.WriteHeader(outputStatus)
+	_, _ = writer.Write(outputBytes)
+}
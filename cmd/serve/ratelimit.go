@@ -0,0 +1,78 @@
+package serve
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	requestsPerSecond = 5
+	requestBurst      = 20
+
+	limiterIdleTimeout = 10 * time.Minute
+	limiterSweepPeriod = time.Minute
+)
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+var (
+	limiters   = map[string]*limiterEntry{}
+	limitersMu sync.Mutex
+	sweepOnce  sync.Once
+)
+
+// limiterFor returns the token bucket for the given remote address, creating
+// it on first use. The address is reduced to its host, since RemoteAddr's
+// ephemeral port changes on every new connection and would otherwise give
+// each one its own bucket.
+func limiterFor(remoteAddr string) *rate.Limiter {
+	sweepOnce.Do(startLimiterSweeper)
+
+	host := hostOnly(remoteAddr)
+
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	entry, ok := limiters[host]
+	if !ok {
+		entry = &limiterEntry{limiter: rate.NewLimiter(requestsPerSecond, requestBurst)}
+		limiters[host] = entry
+	}
+	entry.lastSeen = time.Now()
+	return entry.limiter
+}
+
+// hostOnly strips the port from addr, returning addr unchanged if it isn't
+// in host:port form.
+func hostOnly(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// startLimiterSweeper periodically evicts limiters that haven't been used
+// recently, so the map doesn't grow without bound as distinct IPs churn.
+func startLimiterSweeper() {
+	go func() {
+		ticker := time.NewTicker(limiterSweepPeriod)
+		defer ticker.Stop()
+		for range ticker.C {
+			cutoff := time.Now().Add(-limiterIdleTimeout)
+			limitersMu.Lock()
+			for host, entry := range limiters {
+				if entry.lastSeen.Before(cutoff) {
+					delete(limiters, host)
+				}
+			}
+			limitersMu.Unlock()
+		}
+	}()
+}
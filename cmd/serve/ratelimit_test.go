@@ -0,0 +1,45 @@
+package serve
+
+import "testing"
+
+func TestHostOnly(t *testing.T) {
+	cases := map[string]string{
+		"1.2.3.4:5678": "1.2.3.4",
+		"[::1]:9090":   "::1",
+		"no-port":      "no-port",
+	}
+
+	for addr, want := range cases {
+		if got := hostOnly(addr); got != want {
+			t.Errorf("hostOnly(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestLimiterForSharesBucketByHost(t *testing.T) {
+	a := limiterFor("198.51.100.1:1111")
+	b := limiterFor("198.51.100.1:2222")
+	if a != b {
+		t.Error("limiterFor gave different limiters for the same host on different ports")
+	}
+
+	c := limiterFor("198.51.100.2:1111")
+	if a == c {
+		t.Error("limiterFor gave the same limiter for different hosts")
+	}
+}
+
+func TestLimiterForEnforcesBurst(t *testing.T) {
+	limiter := limiterFor("198.51.100.3:1111")
+
+	allowed := 0
+	for i := 0; i < requestBurst+5; i++ {
+		if limiter.Allow() {
+			allowed++
+		}
+	}
+
+	if allowed != requestBurst {
+		t.Errorf("allowed %d requests through a burst of %d, want exactly %d", allowed, requestBurst, requestBurst)
+	}
+}
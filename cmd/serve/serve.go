@@ -0,0 +1,161 @@
+// Package serve holds the HTTP puzzle server, so it can be run as a
+// subcommand of the puzzles binary instead of being the only thing main does.
+package serve
+
+import (
+	"context"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/csmith/puzzles/internal/puzzle"
+	"github.com/csmith/puzzles/internal/wordlists"
+	"github.com/fsnotify/fsnotify"
+	"golang.org/x/net/websocket"
+)
+
+var (
+	templates         *template.Template
+	templateDirectory string
+	registry          = wordlists.NewRegistry()
+	maxUploadBytes    int64
+	clamAVAddr        string
+)
+
+// Options configures the HTTP puzzle server.
+type Options struct {
+	// WordlistDir overrides the embedded wordlists when set.
+	WordlistDir string
+	// TemplateDir overrides the embedded templates when set.
+	TemplateDir string
+	// MaxUploadBytes caps the size of exifUpload request bodies.
+	MaxUploadBytes int64
+	// ClamAVAddr is the tcp address of a clamd instance to scan uploads
+	// with. Scanning is skipped when it's empty.
+	ClamAVAddr string
+}
+
+// Run starts the HTTP server and blocks until it is shut down.
+func Run(opts Options) error {
+	templateDirectory = opts.TemplateDir
+	maxUploadBytes = opts.MaxUploadBytes
+	clamAVAddr = opts.ClamAVAddr
+
+	log.Printf("Loading wordlist.")
+	if err := registry.Load(opts.WordlistDir); err != nil {
+		return err
+	}
+	registry.Watch()
+	log.Print("Loading templates.")
+	reloadTemplates()
+	templateChanges()
+
+	staticFS, err := fs.Sub(embeddedAssets, "static")
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static", http.FileServer(http.FS(staticFS))))
+	mux.HandleFunc("/favicon.ico", faviconHandler)
+	mux.HandleFunc("/", indexHandler)
+	mux.HandleFunc("/anagram", anagramHandler)
+	mux.HandleFunc("/match", matchHandler)
+	mux.HandleFunc("/exifUpload", exifUpload)
+	mux.Handle("/ws/solve", websocket.Handler(wsSolveHandler))
+	mux.HandleFunc("/api/v1/anagram", apiAnagramHandler)
+	mux.HandleFunc("/api/v1/match", apiMatchHandler)
+	mux.HandleFunc("/api/v1/openapi.yaml", openAPIHandler)
+	mux.HandleFunc("/api/v1/wordlists", wordlistsHandler)
+
+	log.Print("Starting server.")
+	server := http.Server{
+		Addr:    ":8080",
+		Handler: requestLogger(mux),
+	}
+	go func() {
+		_ = server.ListenAndServe()
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, os.Kill)
+	<-stop
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		return err
+	}
+	log.Print("Finishing server.")
+	return nil
+}
+
+func templateChanges() {
+	if templateDirectory == "" {
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Print("Unable to create watcher")
+		return
+	}
+	err = watcher.Add(templateDirectory)
+	if err != nil {
+		log.Print("Unable to watch template folder")
+	}
+	go func() { templateReloader(watcher) }()
+}
+
+func templateReloader(watcher *fsnotify.Watcher) {
+	for {
+		select {
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			reloadTemplates()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Println("error:", err)
+		}
+	}
+}
+
+func reloadTemplates() {
+	if templateDirectory != "" {
+		templates = template.Must(template.ParseFiles(
+			filepath.Join(templateDirectory, "index.html"),
+		))
+		return
+	}
+
+	templates = template.Must(template.ParseFS(embeddedAssets, "templates/index.html"))
+}
+
+func requestLogger(targetMux http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requesterIP := r.RemoteAddr
+		if !limiterFor(requesterIP).Allow() {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write(puzzle.Marshal(puzzle.Output{Success: false, Result: "rate limit exceeded"}))
+			return
+		}
+
+		targetMux.ServeHTTP(w, r)
+		log.Printf(
+			"%s  \t%s  \t%s",
+			requesterIP,
+			r.Method,
+			r.RequestURI,
+		)
+	})
+}
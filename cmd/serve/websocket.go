@@ -0,0 +1,83 @@
+package serve
+
+import (
+	"log"
+	"time"
+
+	"github.com/csmith/puzzles/internal/puzzle"
+	"golang.org/x/net/websocket"
+)
+
+type solveRequest struct {
+	Op        string   `json:"op"`
+	Input     string   `json:"input"`
+	Wordlists []string `json:"wordlists"`
+}
+
+type solveMessage struct {
+	Type  string   `json:"type"`
+	Words []string `json:"words,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+const wsHeartbeatInterval = 15 * time.Second
+
+// wsSolveHandler streams solve results as they're produced, rather than
+// making the caller wait for the whole wordlist to be multiplexed.
+func wsSolveHandler(ws *websocket.Conn) {
+	defer func() {
+		_ = ws.Close()
+	}()
+
+	var req solveRequest
+	if err := websocket.JSON.Receive(ws, &req); err != nil {
+		log.Printf("Unable to read solve request: %s", err.Error())
+		return
+	}
+
+	// done tells the producer goroutine behind results to stop as soon as we
+	// return, so a dropped connection doesn't leave it blocked forever trying
+	// to send a result nobody will ever receive.
+	done := make(chan struct{})
+	defer close(done)
+
+	var results <-chan puzzle.Result
+	switch req.Op {
+	case "anagram":
+		results = puzzle.StreamAnagram(done, registry.Select(req.Wordlists), req.Input)
+	case "match":
+		results = puzzle.StreamMatch(done, registry.Select(req.Wordlists), req.Input)
+	case "regex":
+		stream, err := puzzle.StreamRegexp(done, registry.Words(req.Wordlists), req.Input)
+		if err != nil {
+			_ = websocket.JSON.Send(ws, solveMessage{Type: "error", Error: err.Error()})
+			return
+		}
+		results = stream
+	default:
+		_ = websocket.JSON.Send(ws, solveMessage{Type: "error", Error: "unknown op " + req.Op})
+		return
+	}
+
+	heartbeat := time.NewTicker(wsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				_ = websocket.JSON.Send(ws, solveMessage{Type: "done"})
+				return
+			}
+			if err := websocket.JSON.Send(ws, solveMessage{Type: "result", Words: result.Words}); err != nil {
+				log.Printf("Unable to send solve result: %s", err.Error())
+				return
+			}
+		case <-heartbeat.C:
+			if err := websocket.JSON.Send(ws, solveMessage{Type: "heartbeat"}); err != nil {
+				log.Printf("Unable to send heartbeat: %s", err.Error())
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package puzzle
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+)
+
+var flagPattern = regexp.MustCompile(`flag\{[^}]+\}`)
+
+// GetImageResults scans an uploaded image's raw bytes for embedded flag
+// text. kowalski has no EXIF support, so this doesn't try to parse the
+// image format at all - it just looks for the flag marker anywhere in the
+// file, which is enough to catch flags hidden in metadata or appended data.
+func GetImageResults(file io.Reader) ([]byte, int) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		log.Printf("Unable to read uploaded image: %s", err.Error())
+		return marshal(Output{Success: false, Result: "unable to read image"}), http.StatusInternalServerError
+	}
+
+	matches := flagPattern.FindAll(data, -1)
+	if len(matches) == 0 {
+		return marshal(Output{Success: false, Result: "no flag found"}), http.StatusUnprocessableEntity
+	}
+
+	found := make([]string, len(matches))
+	for i, m := range matches {
+		found[i] = string(m)
+	}
+	return marshal(Output{Success: true, Result: found}), http.StatusOK
+}
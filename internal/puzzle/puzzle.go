@@ -0,0 +1,136 @@
+// Package puzzle holds the solving logic shared by the HTTP server and the
+// "solve" CLI command, so the same multiplexed wordlist search can be driven
+// with or without a web server in front of it.
+package puzzle
+
+import (
+	"encoding/json"
+	"log"
+	"regexp"
+
+	"github.com/csmith/kowalski/v2"
+)
+
+type Output struct {
+	Success bool
+	Result  interface{}
+}
+
+// Result is a single SpellChecker's contribution to a solve, as produced by
+// the solver loop. Streaming consumers (the WS handler) read these directly;
+// the plain JSON handlers collect them into an Output.
+type Result struct {
+	Words []string
+}
+
+type solveFunc func(*kowalski.SpellChecker, string) []string
+
+// Anagram solves an anagram puzzle against every given wordlist.
+func Anagram(words []*kowalski.SpellChecker, input string) ([]byte, int) {
+	return collectResults(StreamAnagram(nil, words, input))
+}
+
+// Match solves a pattern match puzzle against every given wordlist.
+func Match(words []*kowalski.SpellChecker, input string) ([]byte, int) {
+	return collectResults(StreamMatch(nil, words, input))
+}
+
+// Regexp solves a real regular expression against every given wordlist's
+// raw words. Unlike Anagram/Match it can't work from a SpellChecker, since a
+// bloom filter can only answer membership queries, not enumerate its words
+// or support arbitrary regex syntax.
+func Regexp(wordLists [][]string, pattern string) ([]byte, int) {
+	stream, err := StreamRegexp(nil, wordLists, pattern)
+	if err != nil {
+		return marshal(Output{Success: false, Result: err.Error()}), 400
+	}
+	return collectResults(stream)
+}
+
+// StreamAnagram is like Anagram, but emits each SpellChecker's result on a
+// channel as soon as it's produced instead of waiting for all of them. The
+// returned channel is closed once every checker has been consulted, or as
+// soon as done is closed, whichever comes first - so a consumer that stops
+// reading early (e.g. a dropped websocket) doesn't leave the producer
+// goroutine blocked forever.
+func StreamAnagram(done <-chan struct{}, words []*kowalski.SpellChecker, input string) <-chan Result {
+	return streamResults(done, words, input, kowalski.Anagram)
+}
+
+// StreamMatch is like Match, but emits each SpellChecker's result on a
+// channel as soon as it's produced instead of waiting for all of them. See
+// StreamAnagram for the meaning of done.
+func StreamMatch(done <-chan struct{}, words []*kowalski.SpellChecker, input string) <-chan Result {
+	return streamResults(done, words, input, kowalski.Match)
+}
+
+// StreamRegexp is like Regexp, but emits each wordlist's matches on a
+// channel as soon as they're produced instead of waiting for all of them.
+// See StreamAnagram for the meaning of done.
+func StreamRegexp(done <-chan struct{}, wordLists [][]string, pattern string) (<-chan Result, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Result)
+	go func() {
+		defer close(out)
+		for _, words := range wordLists {
+			select {
+			case out <- Result{Words: matchRegexp(re, words)}:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func matchRegexp(re *regexp.Regexp, words []string) []string {
+	var matches []string
+	for _, w := range words {
+		if re.MatchString(w) {
+			matches = append(matches, w)
+		}
+	}
+	return matches
+}
+
+func streamResults(done <-chan struct{}, words []*kowalski.SpellChecker, input string, solve solveFunc) <-chan Result {
+	out := make(chan Result)
+	go func() {
+		defer close(out)
+		for _, checker := range words {
+			select {
+			case out <- Result{Words: solve(checker, input)}:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func collectResults(results <-chan Result) ([]byte, int) {
+	var values []string
+	for result := range results {
+		values = append(values, result.Words...)
+	}
+	return marshal(Output{Success: true, Result: values}), 200
+}
+
+// Marshal encodes o, falling back to a generic failure payload if that's
+// somehow not possible.
+func Marshal(o Output) []byte {
+	return marshal(o)
+}
+
+func marshal(o Output) []byte {
+	b, err := json.Marshal(o)
+	if err != nil {
+		log.Printf("Unable to marshal output: %s", err.Error())
+		return []byte(`{"Success":false,"Result":"internal error"}`)
+	}
+	return b
+}
@@ -0,0 +1,109 @@
+package puzzle
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/csmith/kowalski/v2"
+)
+
+func mustChecker(t *testing.T, words string) *kowalski.SpellChecker {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(words), "\n")
+	checker, err := kowalski.CreateSpellChecker(strings.NewReader(words), len(lines))
+	if err != nil {
+		t.Fatalf("CreateSpellChecker: %s", err.Error())
+	}
+	return checker
+}
+
+// drain reads results until the channel closes, so a test can confirm a
+// producer goroutine actually returned rather than leaking.
+func drain(results <-chan Result, timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case _, ok := <-results:
+			if !ok {
+				return true
+			}
+		case <-deadline:
+			return false
+		}
+	}
+}
+
+func TestStreamMatchClosesWhenDoneClosesBeforeAnyoneReads(t *testing.T) {
+	checker := mustChecker(t, "cat\ndog\nbird\n")
+
+	done := make(chan struct{})
+	results := StreamMatch(done, []*kowalski.SpellChecker{checker}, "???")
+
+	// Nobody reads from results, so the producer is parked on its first send.
+	close(done)
+
+	if !drain(results, time.Second) {
+		t.Fatal("results channel never closed after done was closed - producer goroutine leaked")
+	}
+}
+
+func TestStreamAnagramStopsWhenClientDisconnectsMidStream(t *testing.T) {
+	checkers := []*kowalski.SpellChecker{
+		mustChecker(t, "cat\ndog\n"),
+		mustChecker(t, "ant\nbee\n"),
+		mustChecker(t, "cow\nfox\n"),
+	}
+
+	done := make(chan struct{})
+	results := StreamAnagram(done, checkers, "tac")
+
+	// Simulate a client (e.g. a dropped websocket) that reads one partial
+	// result then goes away.
+	<-results
+	close(done)
+
+	if !drain(results, time.Second) {
+		t.Fatal("results channel never closed after done was closed mid-stream - producer goroutine leaked")
+	}
+}
+
+func TestStreamRegexpStopsWhenDoneCloses(t *testing.T) {
+	wordLists := [][]string{
+		{"cat", "dog"},
+		{"ant", "bee"},
+	}
+
+	done := make(chan struct{})
+	results, err := StreamRegexp(done, wordLists, "^.a.$")
+	if err != nil {
+		t.Fatalf("StreamRegexp: %s", err.Error())
+	}
+
+	<-results
+	close(done)
+
+	if !drain(results, time.Second) {
+		t.Fatal("results channel never closed after done was closed - producer goroutine leaked")
+	}
+}
+
+func TestStreamRegexpMatchesWords(t *testing.T) {
+	wordLists := [][]string{{"cat", "dog", "cot"}}
+
+	results, err := StreamRegexp(nil, wordLists, "^c.t$")
+	if err != nil {
+		t.Fatalf("StreamRegexp: %s", err.Error())
+	}
+
+	result := <-results
+	if len(result.Words) != 2 || result.Words[0] != "cat" || result.Words[1] != "cot" {
+		t.Errorf("StreamRegexp matched %v, want [cat cot]", result.Words)
+	}
+}
+
+func TestStreamRegexpRejectsBadPattern(t *testing.T) {
+	if _, err := StreamRegexp(nil, nil, "("); err == nil {
+		t.Fatal("expected an invalid pattern to return an error")
+	}
+}
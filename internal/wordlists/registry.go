@@ -0,0 +1,239 @@
+// Package wordlists keeps track of the loaded SpellCheckers by name, so
+// handlers can solve against a chosen subset instead of always multiplexing
+// every wordlist ever loaded.
+package wordlists
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/csmith/kowalski/v2"
+	"github.com/fsnotify/fsnotify"
+)
+
+//go:embed wordlists/*
+var embeddedWordlists embed.FS
+
+// Entry describes a single loaded wordlist, as returned by List.
+type Entry struct {
+	Name     string    `json:"name"`
+	Words    int       `json:"words"`
+	LoadedAt time.Time `json:"loaded_at"`
+	SHA256   string    `json:"sha256"`
+}
+
+type loaded struct {
+	checker  *kowalski.SpellChecker
+	rawWords []string
+	words    int
+	loadedAt time.Time
+	sha256   string
+}
+
+// Registry holds the set of loaded wordlists, keyed by name.
+type Registry struct {
+	mu       sync.RWMutex
+	entries  map[string]*loaded
+	override string
+}
+
+// NewRegistry returns an empty Registry. Call Load before using it.
+func NewRegistry() *Registry {
+	return &Registry{entries: map[string]*loaded{}}
+}
+
+// Load populates the registry from overrideDir, or the embedded wordlists
+// if overrideDir is empty.
+func (r *Registry) Load(overrideDir string) error {
+	r.override = overrideDir
+	if overrideDir != "" {
+		return r.loadDir(os.DirFS(overrideDir), ".")
+	}
+	return r.loadDir(embeddedWordlists, "wordlists")
+}
+
+// Watch monitors the override directory for changes, reloading, adding or
+// removing SpellCheckers as files come and go. It's a no-op when the
+// registry was loaded from the embedded wordlists.
+func (r *Registry) Watch() {
+	if r.override == "" {
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Print("Unable to create wordlist watcher")
+		return
+	}
+	if err := watcher.Add(r.override); err != nil {
+		log.Print("Unable to watch wordlist folder")
+	}
+	go r.watchLoop(watcher)
+}
+
+// Select returns the SpellCheckers for the given names, or every loaded
+// SpellChecker if names is empty.
+func (r *Registry) Select(names []string) []*kowalski.SpellChecker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(names) == 0 {
+		checkers := make([]*kowalski.SpellChecker, 0, len(r.entries))
+		for _, e := range r.entries {
+			checkers = append(checkers, e.checker)
+		}
+		return checkers
+	}
+
+	var checkers []*kowalski.SpellChecker
+	for _, name := range names {
+		if e, ok := r.entries[name]; ok {
+			checkers = append(checkers, e.checker)
+		}
+	}
+	return checkers
+}
+
+// Words returns the raw word lists for the given names, or every loaded
+// wordlist if names is empty. Unlike Select, this exposes the words
+// themselves rather than a SpellChecker, for callers (like regex matching)
+// that need to do more than test bloom-filter membership.
+func (r *Registry) Words(names []string) [][]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(names) == 0 {
+		lists := make([][]string, 0, len(r.entries))
+		for _, e := range r.entries {
+			lists = append(lists, e.rawWords)
+		}
+		return lists
+	}
+
+	var lists [][]string
+	for _, name := range names {
+		if e, ok := r.entries[name]; ok {
+			lists = append(lists, e.rawWords)
+		}
+	}
+	return lists
+}
+
+// List returns the metadata for every loaded wordlist, sorted by name.
+func (r *Registry) List() []Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]Entry, 0, len(r.entries))
+	for name, e := range r.entries {
+		list = append(list, Entry{Name: name, Words: e.words, LoadedAt: e.loadedAt, SHA256: e.sha256})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+func (r *Registry) loadDir(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := entry.Name()
+		if dir != "." {
+			path = dir + "/" + path
+		}
+		if err := r.loadFile(fsys, path); err != nil {
+			log.Printf("Unable to load wordlist %s: %s", entry.Name(), err.Error())
+		}
+	}
+	return nil
+}
+
+func (r *Registry) loadFile(fsys fs.FS, path string) error {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return err
+	}
+
+	wordCount := bytes.Count(bytes.TrimSpace(data), []byte("\n")) + 1
+	checker, err := kowalski.CreateSpellChecker(bytes.NewReader(data), wordCount)
+	if err != nil {
+		return err
+	}
+
+	rawWords := rawWordsFrom(data)
+	sum := sha256.Sum256(data)
+	name := nameFor(path)
+
+	r.mu.Lock()
+	r.entries[name] = &loaded{
+		checker:  checker,
+		rawWords: rawWords,
+		words:    wordCount,
+		loadedAt: time.Now(),
+		sha256:   hex.EncodeToString(sum[:]),
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *Registry) remove(path string) {
+	r.mu.Lock()
+	delete(r.entries, nameFor(path))
+	r.mu.Unlock()
+}
+
+func (r *Registry) watchLoop(watcher *fsnotify.Watcher) {
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+				r.remove(event.Name)
+				continue
+			}
+			if err := r.loadFile(os.DirFS(r.override), filepath.Base(event.Name)); err != nil {
+				log.Printf("Unable to reload wordlist %s: %s", event.Name, err.Error())
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Println("error:", err)
+		}
+	}
+}
+
+// rawWordsFrom splits a wordlist file's contents into lowercased words,
+// mirroring the normalisation kowalski.CreateSpellChecker applies internally.
+func rawWordsFrom(data []byte) []string {
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	words := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if word := strings.ToLower(strings.TrimSpace(string(line))); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func nameFor(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
@@ -0,0 +1,89 @@
+package wordlists
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeWordlist(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+		t.Fatalf("writing %s: %s", name, err.Error())
+	}
+}
+
+func TestNameFor(t *testing.T) {
+	cases := map[string]string{
+		"english.txt":         "english",
+		"dir/enable1.txt":     "enable1",
+		"/abs/path/crossword": "crossword",
+		"no-extension-at-all": "no-extension-at-all",
+	}
+
+	for path, want := range cases {
+		if got := nameFor(path); got != want {
+			t.Errorf("nameFor(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRegistryLoadAndSelect(t *testing.T) {
+	dir := t.TempDir()
+	writeWordlist(t, dir, "english.txt", "cat\ndog\nbird\n")
+	writeWordlist(t, dir, "enable1.txt", "ant\nbee\n")
+
+	r := NewRegistry()
+	if err := r.Load(dir); err != nil {
+		t.Fatalf("Load: %s", err.Error())
+	}
+
+	list := r.List()
+	if len(list) != 2 {
+		t.Fatalf("List() returned %d entries, want 2", len(list))
+	}
+	if list[0].Name != "enable1" || list[1].Name != "english" {
+		t.Fatalf("List() = %+v, want entries sorted as enable1, english", list)
+	}
+	if list[1].Words != 3 {
+		t.Errorf("english entry has %d words, want 3", list[1].Words)
+	}
+
+	if checkers := r.Select(nil); len(checkers) != 2 {
+		t.Errorf("Select(nil) returned %d checkers, want 2", len(checkers))
+	}
+
+	if checkers := r.Select([]string{"english"}); len(checkers) != 1 {
+		t.Errorf("Select([english]) returned %d checkers, want 1", len(checkers))
+	}
+
+	if checkers := r.Select([]string{"missing"}); len(checkers) != 0 {
+		t.Errorf("Select([missing]) returned %d checkers, want 0", len(checkers))
+	}
+
+	if words := r.Words([]string{"enable1"}); len(words) != 1 || len(words[0]) != 2 {
+		t.Errorf("Words([enable1]) = %+v, want one list of 2 words", words)
+	}
+}
+
+func TestRegistryRemove(t *testing.T) {
+	dir := t.TempDir()
+	writeWordlist(t, dir, "english.txt", "cat\ndog\n")
+
+	r := NewRegistry()
+	if err := r.Load(dir); err != nil {
+		t.Fatalf("Load: %s", err.Error())
+	}
+	if len(r.List()) != 1 {
+		t.Fatalf("expected 1 entry after Load, got %d", len(r.List()))
+	}
+
+	r.remove(filepath.Join(dir, "english.txt"))
+
+	if len(r.List()) != 0 {
+		t.Fatalf("expected 0 entries after remove, got %d", len(r.List()))
+	}
+	if checkers := r.Select(nil); len(checkers) != 0 {
+		t.Errorf("Select(nil) returned %d checkers after remove, want 0", len(checkers))
+	}
+}
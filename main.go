@@ -1,170 +1,126 @@
 package main
 
 import (
-	"context"
-	"flag"
-	"html/template"
+	"fmt"
+	"io"
 	"log"
-	"net/http"
 	"os"
-	"os/signal"
-	"path/filepath"
-	"time"
+	"strings"
 
-	"github.com/csmith/kowalski/v2"
-	"github.com/fsnotify/fsnotify"
-	"github.com/kouhin/envflag"
+	"github.com/csmith/puzzles/cmd/serve"
+	"github.com/csmith/puzzles/internal/puzzle"
+	"github.com/csmith/puzzles/internal/wordlists"
+	"github.com/urfave/cli"
 )
 
-var (
-	templates         *template.Template
-	wordList          = flag.String("wordlist-dir", "/app/wordlists", "Path of the word list directory")
-	templateDirectory = flag.String("template-dir", "/app/templates", "Path of the templates directory")
-	words             []*kowalski.SpellChecker
-	download		  = flag.Bool("download-flags", false, "Download new flags data")
-)
-
-type Output struct {
-	Success bool
-	Result  interface{}
+// sharedFlags is attached to both the app and every command, so they're
+// accepted whether given before or after the subcommand name (e.g. both
+// `puzzles -wordlist-dir=x serve` and `puzzles serve -wordlist-dir=x`).
+var sharedFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:   "wordlist-dir",
+		Usage:  "Path of the word list directory, overriding the embedded wordlists",
+		EnvVar: "WORDLIST_DIR",
+	},
+	cli.StringFlag{
+		Name:   "template-dir",
+		Usage:  "Path of the templates directory, overriding the embedded templates",
+		EnvVar: "TEMPLATE_DIR",
+	},
+	cli.Int64Flag{
+		Name:   "max-upload-bytes",
+		Usage:  "Maximum size in bytes accepted for exif image uploads",
+		Value:  10 << 20,
+		EnvVar: "MAX_UPLOAD_BYTES",
+	},
+	cli.StringFlag{
+		Name:   "clamav-addr",
+		Usage:  "Address of a clamd instance to scan uploads with, e.g. localhost:3310. Disabled if empty",
+		EnvVar: "CLAMAV_ADDR",
+	},
 }
 
-//go:generate go run . -download-flags
-
 func main() {
-	err := envflag.Parse()
-	if err != nil {
-		log.Fatalf("Unable to parse flags: %s", err.Error())
-	}
-	if *download {
-		downloadFlags()
-		return
-	}
-	log.Printf("Loading wordlist.")
-	words = loadWords(*wordList)
-	log.Print("Loading templates.")
-	reloadTemplates()
-	templateChanges()
-	mux := http.NewServeMux()
-	mux.Handle("/static/", http.StripPrefix("/static", http.FileServer(http.Dir(filepath.Join(".", "static")))))
-	mux.HandleFunc("/favicon.ico", faviconHandler)
-	mux.HandleFunc("/", indexHandler)
-	mux.HandleFunc("/anagram", anagramHandler)
-	mux.HandleFunc("/match", matchHandler)
-	mux.HandleFunc("/exifUpload", exifUpload)
-	log.Print("Starting server.")
-	server := http.Server{
-		Addr:    ":8080",
-		Handler: requestLogger(mux),
+	app := cli.NewApp()
+	app.Name = "puzzles"
+	app.Usage = "Solve crossword-style puzzles, from the command line or over HTTP"
+	app.Flags = sharedFlags
+	app.Commands = []cli.Command{
+		{
+			Name:   "serve",
+			Usage:  "Run the HTTP puzzle server",
+			Flags:  sharedFlags,
+			Action: serveAction,
+		},
+		{
+			Name:      "solve",
+			Usage:     "Solve an anagram, match or regex puzzle read from stdin",
+			ArgsUsage: "<anagram|match|regex>",
+			Flags:     sharedFlags,
+			Action:    solveAction,
+		},
 	}
-	go func() {
-		_ = server.ListenAndServe()
-	}()
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, os.Kill)
-	<-stop
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Unable to shutdown: %s", err.Error())
-	}
-	log.Print("Finishing server.")
-}
 
-func templateChanges() {
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		log.Print("Unable to create watcher")
-		return
-	}
-	err = watcher.Add(filepath.Join("./templates"))
-	if err != nil {
-		log.Print("Unable to watch template folder")
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
 	}
-	go func() { templateReloader(watcher) }()
 }
 
-func templateReloader(watcher *fsnotify.Watcher) {
-	for {
-		select {
-		case _, ok := <-watcher.Events:
-			if !ok {
-				return
-			}
-			reloadTemplates()
-		case err, ok := <-watcher.Errors:
-			if !ok {
-				return
-			}
-			log.Println("error:", err)
-		}
+// stringFlag resolves name from the command's own flags if it was given
+// there, falling back to the flag given before the subcommand (or its
+// env var), so either position works.
+func stringFlag(c *cli.Context, name string) string {
+	if c.IsSet(name) {
+		return c.String(name)
 	}
+	return c.GlobalString(name)
 }
 
-func reloadTemplates() {
-	templates = template.Must(template.ParseFiles(
-		filepath.Join(*templateDirectory, "index.html"),
-	))
+// int64Flag is stringFlag for int64-valued flags.
+func int64Flag(c *cli.Context, name string) int64 {
+	if c.IsSet(name) {
+		return c.Int64(name)
+	}
+	return c.GlobalInt64(name)
 }
 
-func requestLogger(targetMux http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		targetMux.ServeHTTP(w, r)
-		requesterIP := r.RemoteAddr
-		log.Printf(
-			"%s  \t%s  \t%s",
-			requesterIP,
-			r.Method,
-			r.RequestURI,
-		)
+func serveAction(c *cli.Context) error {
+	return serve.Run(serve.Options{
+		WordlistDir:    stringFlag(c, "wordlist-dir"),
+		TemplateDir:    stringFlag(c, "template-dir"),
+		MaxUploadBytes: int64Flag(c, "max-upload-bytes"),
+		ClamAVAddr:     stringFlag(c, "clamav-addr"),
 	})
 }
 
-func indexHandler(writer http.ResponseWriter, request *http.Request) {
-	if request.URL.Path == "/" {
-		err := templates.ExecuteTemplate(writer, "index.html", "")
-		if err != nil {
-			log.Printf("Fucked up: %s", err.Error())
-			writer.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-	} else {
-		writer.WriteHeader(http.StatusNotFound)
+func solveAction(c *cli.Context) error {
+	op := c.Args().Get(0)
+	if op != "anagram" && op != "match" && op != "regex" {
+		return cli.NewExitError(fmt.Sprintf("unknown solve mode %q, expected anagram, match or regex", op), 1)
 	}
-}
 
-func faviconHandler(w http.ResponseWriter, r *http.Request) {
-	http.ServeFile(w, r, filepath.Join(".", "static", "favicon.ico"))
-}
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
 
-func anagramHandler(writer http.ResponseWriter, request *http.Request) {
-	input := request.FormValue("input")
-	writer.Header().Add("Content-Type", "application/json")
-	outputBytes, outputStatus := getResults(words, input, kowalski.MultiplexAnagram)
-	writer.WriteHeader(outputStatus)
-	_, _ = writer.Write(outputBytes)
-}
+	registry := wordlists.NewRegistry()
+	if err := registry.Load(stringFlag(c, "wordlist-dir")); err != nil {
+		return err
+	}
 
-func matchHandler(writer http.ResponseWriter, request *http.Request) {
-	input := request.FormValue("input")
-	writer.Header().Add("Content-Type", "application/json")
-	outputBytes, outputStatus := getResults(words, input, kowalski.MultiplexMatch)
-	writer.WriteHeader(outputStatus)
-	_, _ = writer.Write(outputBytes)
-}
+	trimmedInput := strings.TrimSpace(string(input))
 
-func exifUpload(writer http.ResponseWriter, request *http.Request) {
-	file, _, err := request.FormFile("exifFile")
-	if err != nil {
-		writer.WriteHeader(http.StatusInternalServerError)
-		_, _ = writer.Write([]byte("Error"))
-		log.Println("Error Getting File", err)
-		return
+	var outputBytes []byte
+	switch op {
+	case "anagram":
+		outputBytes, _ = puzzle.Anagram(registry.Select(nil), trimmedInput)
+	case "match":
+		outputBytes, _ = puzzle.Match(registry.Select(nil), trimmedInput)
+	case "regex":
+		outputBytes, _ = puzzle.Regexp(registry.Words(nil), trimmedInput)
 	}
-	defer func() {
-		_ = file.Close()
-	}()
-	outputBytes, outputStatus := getImageResults(file)
-	writer.WriteHeader(outputStatus)
-	_, _ = writer.Write(outputBytes)
+
+	_, err = os.Stdout.Write(outputBytes)
+	return err
 }